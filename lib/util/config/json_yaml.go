@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//------------------------------------------------------------------------------
+
+// MarshalJSONViaYAML marshals v to JSON by first marshalling it to YAML (thus
+// honouring `yaml:"..."` struct tags, the same tags Sanitised.MarshalYAML
+// uses) and then converting the resulting document into JSON. This avoids the
+// asymmetry of hand-rolled JSON marshalling that ignores yaml tag semantics
+// such as `omitempty`.
+func MarshalJSONViaYAML(v interface{}) ([]byte, error) {
+	yBytes, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err = yaml.Unmarshal(yBytes, &generic); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(generic)
+}
+
+// UnmarshalJSONViaYAML unmarshals JSON data into v by first converting it to
+// a YAML node tree and then unmarshalling that tree into v, so that v's
+// `yaml:"..."` struct tags are honoured rather than its (possibly absent or
+// inconsistent) `json:"..."` tags.
+//
+// JSON numbers are decoded straight into YAML scalar nodes using their
+// original textual representation (via json.Number), rather than through a
+// float64 generic hop, so ids, unix-nano timestamps and other integers beyond
+// 2^53 don't silently lose precision before v's own field types get a chance
+// to parse them.
+func UnmarshalJSONViaYAML(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return err
+	}
+
+	node, err := jsonValueToNode(generic)
+	if err != nil {
+		return err
+	}
+
+	yBytes, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(yBytes, v)
+}
+
+// jsonValueToNode converts a value produced by a json.Decoder with UseNumber
+// enabled into an equivalent *yaml.Node tree, preserving the exact textual
+// representation of numbers.
+func jsonValueToNode(v interface{}) (*yaml.Node, error) {
+	switch t := v.(type) {
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}, nil
+	case bool:
+		val := "false"
+		if t {
+			val = "true"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: val}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case map[string]interface{}:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			valNode, err := jsonValueToNode(t[k])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, valNode)
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range t {
+			itemNode, err := jsonValueToNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, itemNode)
+		}
+		return node, nil
+	default:
+		return nil, fmt.Errorf("unsupported JSON value type %T", v)
+	}
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalJSONViaYAMLOmitsEmpty(t *testing.T) {
+	type inner struct {
+		Timeout string `yaml:"timeout,omitempty"`
+	}
+	type conf struct {
+		Type  string `yaml:"type"`
+		Inner inner  `yaml:"inner,omitempty"`
+	}
+
+	jBytes, err := MarshalJSONViaYAML(conf{Type: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var generic map[string]interface{}
+	if err := json.Unmarshal(jBytes, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := generic["inner"]; exists {
+		t.Errorf("expected empty inner struct to be omitted, got: %s", jBytes)
+	}
+}
+
+func TestUnmarshalJSONViaYAMLPreservesLargeIntPrecision(t *testing.T) {
+	type conf struct {
+		ID int64 `yaml:"id"`
+	}
+
+	// Larger than 2^53, where float64 would start losing precision.
+	const want = int64(9007199254740993)
+
+	data, err := json.Marshal(map[string]interface{}{"id": want})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c conf
+	if err := UnmarshalJSONViaYAML(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.ID != want {
+		t.Errorf("wrong id: %v != %v", c.ID, want)
+	}
+}
+
+func TestUnmarshalJSONViaYAMLRoundTrip(t *testing.T) {
+	type conf struct {
+		Type    string  `yaml:"type"`
+		Timeout float64 `yaml:"timeout"`
+		Retries int     `yaml:"retries"`
+	}
+
+	orig := conf{Type: "foo", Timeout: 1.5, Retries: 3}
+
+	jBytes, err := MarshalJSONViaYAML(orig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var roundTripped conf
+	if err := UnmarshalJSONViaYAML(jBytes, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	if roundTripped != orig {
+		t.Errorf("round trip mismatch: %+v != %+v", roundTripped, orig)
+	}
+}
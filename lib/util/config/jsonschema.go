@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//------------------------------------------------------------------------------
+
+// ComponentSpec describes a registered component type (an input, output,
+// processor, etc.) for the purposes of documentation and JSON Schema
+// generation.
+type ComponentSpec struct {
+	// Name is the component's "type" value.
+	Name string
+
+	// Fields describes the config fields nested under the component's type
+	// namespace.
+	Fields []FieldSpec
+}
+
+// componentsByKind holds registered ComponentSpecs grouped by kind, e.g.
+// "input", "output", "processor".
+var componentsByKind = map[string][]ComponentSpec{}
+
+// RegisterComponentSpec registers a component spec under a kind (such as
+// "input" or "processor"), making it discoverable to GenerateJSONSchema. It
+// also registers the spec's secret field names with RegisterFieldSpecs.
+func RegisterComponentSpec(kind string, spec ComponentSpec) {
+	componentsByKind[kind] = append(componentsByKind[kind], spec)
+	RegisterFieldSpecs(spec.Name, spec.Fields)
+}
+
+// GenerateJSONSchema generates a draft-07 JSON Schema describing the
+// discriminated union of all components registered under kind, keyed on
+// their "type" field. The schema allows editors to offer autocompletion and
+// validation against live Benthos configs.
+func GenerateJSONSchema(kind string) ([]byte, error) {
+	specs, exists := componentsByKind[kind]
+	if !exists || len(specs) == 0 {
+		return nil, fmt.Errorf("no components registered for kind %q", kind)
+	}
+
+	oneOf := make([]interface{}, len(specs))
+	for i, spec := range specs {
+		oneOf[i] = componentSchema(spec)
+	}
+
+	schema := map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title":   kind + " config",
+		"oneOf":   oneOf,
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func componentSchema(spec ComponentSpec) map[string]interface{} {
+	fieldProps := map[string]interface{}{}
+	var required []string
+	for _, f := range spec.Fields {
+		fieldProps[f.Name] = fieldSchema(f)
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+
+	fieldsSchema := map[string]interface{}{
+		"type":       "object",
+		"properties": fieldProps,
+	}
+	if len(required) > 0 {
+		fieldsSchema["required"] = required
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type":    map[string]interface{}{"const": spec.Name},
+			spec.Name: fieldsSchema,
+		},
+		"required": []string{"type", spec.Name},
+	}
+}
+
+func fieldSchema(f FieldSpec) map[string]interface{} {
+	s := map[string]interface{}{}
+	if f.Type != "" {
+		s["type"] = f.Type
+	}
+	if f.Description != "" {
+		s["description"] = f.Description
+	}
+	if f.Default != nil {
+		s["default"] = f.Default
+	}
+	if len(f.Examples) > 0 {
+		s["examples"] = f.Examples
+	}
+	return s
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGenerateJSONSchema(t *testing.T) {
+	defer delete(componentsByKind, "test_kind")
+
+	RegisterComponentSpec("test_kind", ComponentSpec{
+		Name: "foo",
+		Fields: []FieldSpec{
+			{Name: "url", Type: "string", Required: true},
+			{Name: "password", Type: "string", Secret: true},
+		},
+	})
+
+	schemaBytes, err := GenerateJSONSchema("test_kind")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(schemaBytes, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	if schema["$schema"] != "http://json-schema.org/draft-07/schema#" {
+		t.Errorf("unexpected $schema: %v", schema["$schema"])
+	}
+
+	oneOf, ok := schema["oneOf"].([]interface{})
+	if !ok || len(oneOf) != 1 {
+		t.Fatalf("expected a single oneOf entry, got: %v", schema["oneOf"])
+	}
+
+	component := oneOf[0].(map[string]interface{})
+	props := component["properties"].(map[string]interface{})
+	fooSchema := props["foo"].(map[string]interface{})
+	fooProps := fooSchema["properties"].(map[string]interface{})
+	if _, exists := fooProps["url"]; !exists {
+		t.Errorf("expected url field in schema, got: %v", fooProps)
+	}
+
+	required, _ := fooSchema["required"].([]interface{})
+	if len(required) != 1 || required[0] != "url" {
+		t.Errorf("expected only url to be required, got: %v", required)
+	}
+}
+
+func TestGenerateJSONSchemaUnknownKind(t *testing.T) {
+	if _, err := GenerateJSONSchema("does_not_exist"); err == nil {
+		t.Errorf("expected an error for an unregistered kind")
+	}
+}
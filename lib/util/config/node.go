@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+
+	"gopkg.in/yaml.v3"
+)
+
+//------------------------------------------------------------------------------
+
+// SanitizeComponentNode performs the same sanitation as SanitizeComponent, but
+// operates directly on a *yaml.Node tree rather than round-tripping through a
+// map[string]interface{}. This preserves comments and key ordering on the
+// retained nodes, and (unless opts.PreserveAnchors is set) resolves aliases to
+// their anchored content.
+func SanitizeComponentNode(root *yaml.Node, opts SanitizeOpts) (*yaml.Node, error) {
+	mapping := root
+	if mapping.Kind == yaml.DocumentNode {
+		if len(mapping.Content) == 0 {
+			return nil, errors.New("attempted to sanitize an empty yaml document")
+		}
+		mapping = mapping.Content[0]
+	}
+	if !opts.PreserveAnchors {
+		mapping = resolveAliasesNode(mapping)
+	}
+	if mapping.Kind != yaml.MappingNode {
+		return nil, errors.New("attempted to sanitize a non-mapping node")
+	}
+
+	var typeKey, typeVal *yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == "type" {
+			typeKey, typeVal = mapping.Content[i], mapping.Content[i+1]
+			break
+		}
+	}
+	if typeKey == nil || typeVal.Kind != yaml.ScalarNode {
+		return nil, errors.New("attempted to sanitize config without a type field")
+	}
+	typeStr := typeVal.Value
+
+	sanitised := &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Tag:     mapping.Tag,
+		Content: []*yaml.Node{typeKey, typeVal},
+	}
+
+	var matchedKey, matchedVal *yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == typeStr {
+			matchedKey, matchedVal = mapping.Content[i], mapping.Content[i+1]
+			break
+		}
+	}
+	if matchedKey == nil {
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == "plugin" && mapping.Content[i+1].Tag != "!!null" {
+				matchedKey, matchedVal = mapping.Content[i], mapping.Content[i+1]
+				break
+			}
+		}
+	}
+	if matchedKey != nil {
+		sanitised.Content = append(sanitised.Content, matchedKey, matchedVal)
+	}
+
+	if root.Kind == yaml.DocumentNode {
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{sanitised}}, nil
+	}
+	return sanitised, nil
+}
+
+// resolveAliasesNode returns a copy of node with any AliasNode replaced by its
+// anchored content, recursively.
+func resolveAliasesNode(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return resolveAliasesNode(node.Alias)
+	}
+
+	out := *node
+	out.Anchor = ""
+	if len(node.Content) > 0 {
+		out.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			out.Content[i] = resolveAliasesNode(child)
+		}
+	}
+	return &out
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func sanitizeYAMLNode(t *testing.T, src string, opts SanitizeOpts) string {
+	t.Helper()
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := SanitizeComponentNode(&doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outBytes, err := yaml.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(outBytes)
+}
+
+func TestSanitizeComponentNodePrefersTypeBlockOverPlugin(t *testing.T) {
+	src := `
+type: foo
+foo:
+  url: http://example.com
+plugin:
+  unused: true
+`
+	out := sanitizeYAMLNode(t, src, SanitizeOpts{})
+	if strings.Contains(out, "plugin") {
+		t.Errorf("expected plugin block to be dropped when a matching type block exists, got: %s", out)
+	}
+	if !strings.Contains(out, "foo:") {
+		t.Errorf("expected type block to be retained, got: %s", out)
+	}
+}
+
+func TestSanitizeComponentNodeFallsBackToPlugin(t *testing.T) {
+	src := `
+type: foo
+plugin:
+  url: http://example.com
+`
+	out := sanitizeYAMLNode(t, src, SanitizeOpts{})
+	if !strings.Contains(out, "plugin:") {
+		t.Errorf("expected plugin block to be retained when no matching type block exists, got: %s", out)
+	}
+}
+
+func TestSanitizeComponentNodePreservesComments(t *testing.T) {
+	src := `
+# a comment on type
+type: foo # inline
+foo:
+  url: http://example.com # keep this
+bar:
+  unused: true
+`
+	out := sanitizeYAMLNode(t, src, SanitizeOpts{})
+	if !strings.Contains(out, "# a comment on type") {
+		t.Errorf("expected head comment to be preserved, got: %s", out)
+	}
+	if !strings.Contains(out, "# keep this") {
+		t.Errorf("expected line comment to be preserved, got: %s", out)
+	}
+	if strings.Contains(out, "bar:") {
+		t.Errorf("expected unrelated block to be dropped, got: %s", out)
+	}
+}
+
+func TestSanitizeComponentNodeResolvesAliasesWithoutLeakingAnchor(t *testing.T) {
+	src := `
+type: foo
+foo:
+  request: &req
+    url: http://example.com
+  retry: *req
+`
+	out := sanitizeYAMLNode(t, src, SanitizeOpts{})
+	if strings.Contains(out, "&req") || strings.Contains(out, "*req") {
+		t.Errorf("expected aliases to be fully resolved with no stray anchor, got: %s", out)
+	}
+}
+
+func TestSanitizeComponentNodePreservesAnchorsWhenRequested(t *testing.T) {
+	src := `
+type: foo
+foo:
+  request: &req
+    url: http://example.com
+  retry: *req
+`
+	out := sanitizeYAMLNode(t, src, SanitizeOpts{PreserveAnchors: true})
+	if !strings.Contains(out, "&req") || !strings.Contains(out, "*req") {
+		t.Errorf("expected anchor/alias to be preserved, got: %s", out)
+	}
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//------------------------------------------------------------------------------
+
+// filterSanitisedPaths returns a copy of sanitised restricted to the
+// subtrees matched by include (if non-empty, only these survive, plus the
+// top-level "type" field) and with the subtrees matched by exclude removed
+// afterwards.
+//
+// Paths use the same YAMLPath syntax as Query and Patch (dotted keys, `[n]`
+// indexing, `*` wildcards and `..` recursive descent); a leading "$" is
+// optional, so both "$.output.timeout" and "output.timeout" are accepted.
+func filterSanitisedPaths(sanitised Sanitised, include, exclude []string) (Sanitised, error) {
+	root, err := nodeFromConf(sanitised)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(include) > 0 {
+		targets, err := queryNodePaths(root, include)
+		if err != nil {
+			return nil, err
+		}
+		root, err = keepOnlyNodes(root, targets)
+		if err != nil {
+			return nil, err
+		}
+		if typeVal, exists := sanitised["type"]; exists {
+			if err := ensureTypeField(root, typeVal); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(exclude) > 0 {
+		targets, err := queryNodePaths(root, exclude)
+		if err != nil {
+			return nil, err
+		}
+		pruneNodes(root, targets)
+	}
+
+	// Decode into a plain map[string]interface{} rather than Sanitised
+	// directly: yaml.v3 propagates a named map[K]interface{} decode target
+	// to every nested mapping decoded into an interface{} slot, which would
+	// otherwise turn every nested value (e.g. sanitised["foo"]) into a
+	// Sanitised too, breaking the map[string]interface{} type assertions
+	// used elsewhere in this package (redactSecretsInValue, for instance).
+	var generic map[string]interface{}
+	if err := root.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return Sanitised(generic), nil
+}
+
+// queryNodePaths evaluates each of paths against root and returns the set of
+// every node they match.
+func queryNodePaths(root *yaml.Node, paths []string) (map[*yaml.Node]bool, error) {
+	targets := map[*yaml.Node]bool{}
+	for _, p := range paths {
+		segs, err := parseYAMLPath(ensureRootPrefix(p))
+		if err != nil {
+			return nil, err
+		}
+		matches, err := queryNodePath(root, segs)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			targets[m] = true
+		}
+	}
+	return targets, nil
+}
+
+// ensureRootPrefix allows sanitization filter paths to omit the leading "$"
+// that Query and Patch require.
+func ensureRootPrefix(path string) string {
+	if strings.HasPrefix(path, "$") {
+		return path
+	}
+	if strings.HasPrefix(path, ".") {
+		return "$" + path
+	}
+	return "$." + path
+}
+
+// keepOnlyNodes returns a copy of root containing only the nodes in targets,
+// along with whatever mapping/sequence ancestors are needed to reach them.
+func keepOnlyNodes(root *yaml.Node, targets map[*yaml.Node]bool) (*yaml.Node, error) {
+	parents := map[*yaml.Node]*yaml.Node{}
+	buildParentMap(root, parents)
+
+	keep := map[*yaml.Node]bool{root: true}
+	for t := range targets {
+		for n := t; n != nil; n = parents[n] {
+			keep[n] = true
+		}
+	}
+
+	return copyKept(root, targets, keep), nil
+}
+
+// buildParentMap records, for every mapping value and sequence element
+// reachable from root, the container node that holds it.
+func buildParentMap(node *yaml.Node, parents map[*yaml.Node]*yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			val := node.Content[i+1]
+			parents[val] = node
+			buildParentMap(val, parents)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			parents[item] = node
+			buildParentMap(item, parents)
+		}
+	}
+}
+
+// copyKept returns a copy of node, keeping matched target subtrees whole and
+// recursing into ancestor-only nodes to drop any branch not in keep.
+func copyKept(node *yaml.Node, targets, keep map[*yaml.Node]bool) *yaml.Node {
+	if targets[node] {
+		return node
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		out := &yaml.Node{Kind: yaml.MappingNode, Tag: node.Tag}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			if !keep[val] {
+				continue
+			}
+			out.Content = append(out.Content, key, copyKept(val, targets, keep))
+		}
+		return out
+	case yaml.SequenceNode:
+		out := &yaml.Node{Kind: yaml.SequenceNode, Tag: node.Tag}
+		for _, item := range node.Content {
+			if !keep[item] {
+				continue
+			}
+			out.Content = append(out.Content, copyKept(item, targets, keep))
+		}
+		return out
+	default:
+		return node
+	}
+}
+
+// pruneNodes removes, in place, every mapping entry or sequence element whose
+// value is in targets.
+func pruneNodes(node *yaml.Node, targets map[*yaml.Node]bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		var kept []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			if targets[val] {
+				continue
+			}
+			pruneNodes(val, targets)
+			kept = append(kept, key, val)
+		}
+		node.Content = kept
+	case yaml.SequenceNode:
+		var kept []*yaml.Node
+		for _, item := range node.Content {
+			if targets[item] {
+				continue
+			}
+			pruneNodes(item, targets)
+			kept = append(kept, item)
+		}
+		node.Content = kept
+	}
+}
+
+// ensureTypeField prepends a "type" key to root if it isn't already present,
+// so include-path filtering never drops the field that identifies the
+// component.
+func ensureTypeField(root *yaml.Node, typeVal interface{}) error {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "type" {
+			return nil
+		}
+	}
+
+	var valNode yaml.Node
+	if err := valNode.Encode(typeVal); err != nil {
+		return err
+	}
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "type"}
+	root.Content = append([]*yaml.Node{keyNode, &valNode}, root.Content...)
+	return nil
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,51 @@
+package config
+
+import "gopkg.in/yaml.v3"
+
+//------------------------------------------------------------------------------
+
+// PrintYAML sanitizes a component config supplied as raw YAML and returns it
+// re-marshalled as YAML, preserving comments and (unless opts.PreserveAnchors
+// is set) resolving anchors.
+func PrintYAML(yamlBytes []byte, opts SanitizeOpts) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(yamlBytes, &doc); err != nil {
+		return nil, err
+	}
+
+	sanitised, err := SanitizeComponentNode(&doc, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(sanitised)
+}
+
+// PrintJSON sanitizes conf and returns it as JSON. When viaYAML is set the
+// JSON is produced via MarshalJSONViaYAML rather than Sanitised's own
+// MarshalJSON. Both start from the same sanitised map, which
+// SanitizeComponentWithOpts builds from a YAML round trip of conf, so yaml tag
+// semantics such as `omitempty` apply either way; the viaYAML output orders
+// keys alphabetically and does not put "type" first.
+func PrintJSON(conf interface{}, opts SanitizeOpts, viaYAML bool) ([]byte, error) {
+	sanitised, err := SanitizeComponentWithOpts(conf, opts)
+	if err != nil {
+		return nil, err
+	}
+	if viaYAML {
+		return MarshalJSONViaYAML(sanitised)
+	}
+	return sanitised.MarshalJSON()
+}
+
+// PrintJSONSchema returns a draft-07 JSON Schema for kind.
+func PrintJSONSchema(kind string) ([]byte, error) {
+	return GenerateJSONSchema(kind)
+}
+
+// ConfigPathFunction evaluates a YAMLPath expression against conf.
+func ConfigPathFunction(conf interface{}, path string) (interface{}, error) {
+	return Query(conf, path)
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//------------------------------------------------------------------------------
+
+// pathSeg is a single step of a parsed YAMLPath expression.
+type pathSeg struct {
+	recursive bool
+	key       string
+	wildcard  bool
+	index     int
+	hasIndex  bool
+}
+
+// parseYAMLPath parses a subset of JSONPath syntax over a YAML document: dot
+// separated keys, `[n]` sequence indexing, `*` wildcards and `..` recursive
+// descent, e.g. "$.pipeline.processors[0].branch.request_map" or
+// "$..password".
+func parseYAMLPath(path string) ([]pathSeg, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("yamlpath must start with '$': %q", path)
+	}
+	rest := path[1:]
+
+	var segs []pathSeg
+	for i := 0; i < len(rest); {
+		recursive := false
+		switch {
+		case strings.HasPrefix(rest[i:], ".."):
+			recursive = true
+			i += 2
+		case rest[i] == '.':
+			i++
+		default:
+			return nil, fmt.Errorf("invalid yamlpath syntax at %q", rest[i:])
+		}
+
+		start := i
+		for i < len(rest) && rest[i] != '.' && rest[i] != '[' {
+			i++
+		}
+		key := rest[start:i]
+		if key == "" && !recursive {
+			return nil, fmt.Errorf("invalid yamlpath syntax at %q", rest[start:])
+		}
+		if key != "" {
+			if key == "*" {
+				segs = append(segs, pathSeg{recursive: recursive, wildcard: true})
+			} else {
+				segs = append(segs, pathSeg{recursive: recursive, key: key})
+			}
+		}
+
+		for i < len(rest) && rest[i] == '[' {
+			end := strings.IndexByte(rest[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in yamlpath: %q", rest)
+			}
+			idxStr := rest[i+1 : i+end]
+			i += end + 1
+			if idxStr == "*" {
+				segs = append(segs, pathSeg{wildcard: true})
+			} else {
+				idx, err := strconv.Atoi(idxStr)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q in yamlpath", idxStr)
+				}
+				segs = append(segs, pathSeg{index: idx, hasIndex: true})
+			}
+		}
+	}
+	return segs, nil
+}
+
+//------------------------------------------------------------------------------
+
+// Query evaluates a YAMLPath expression against conf and returns the first
+// matching value. Paths are resolved against the YAML node representation of
+// conf, so they follow through anchors and aliases.
+func Query(conf interface{}, path string) (interface{}, error) {
+	node, err := nodeFromConf(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	segs, err := parseYAMLPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := queryNodePath(node, segs)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("yamlpath %q matched no fields", path)
+	}
+
+	var result interface{}
+	if err := matches[0].Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Patch evaluates a YAMLPath expression against conf and overwrites every
+// matching field with value. conf must be a pointer so the patched result can
+// be written back into it.
+func Patch(conf interface{}, path string, value interface{}) error {
+	node, err := nodeFromConf(conf)
+	if err != nil {
+		return err
+	}
+
+	segs, err := parseYAMLPath(path)
+	if err != nil {
+		return err
+	}
+
+	matches, err := queryNodePath(node, segs)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("yamlpath %q matched no fields", path)
+	}
+
+	if err := applyPatchValue(matches, value); err != nil {
+		return err
+	}
+
+	outBytes, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(outBytes, conf)
+}
+
+// applyPatchValue overwrites every node in matches with its own freshly
+// encoded copy of value. Each match gets an independent Encode call (rather
+// than one shared yaml.Node reused across matches) so that, for non-scalar
+// values, mutating the Content of one match can never be observed through
+// another.
+func applyPatchValue(matches []*yaml.Node, value interface{}) error {
+	for _, m := range matches {
+		var valNode yaml.Node
+		if err := valNode.Encode(value); err != nil {
+			return err
+		}
+		*m = valNode
+	}
+	return nil
+}
+
+// nodeFromConf marshals conf to YAML and returns the root content node (the
+// document node is unwrapped, since callers only care about its content).
+func nodeFromConf(conf interface{}) (*yaml.Node, error) {
+	cBytes, err := yaml.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(cBytes, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0], nil
+	}
+	return &doc, nil
+}
+
+// queryNodePath resolves segs against node, returning every matching node.
+func queryNodePath(node *yaml.Node, segs []pathSeg) ([]*yaml.Node, error) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if len(segs) == 0 {
+		return []*yaml.Node{node}, nil
+	}
+
+	seg := segs[0]
+	rest := segs[1:]
+
+	candidates := []*yaml.Node{node}
+	if seg.recursive {
+		candidates = collectNodes(node)
+	}
+
+	var matches []*yaml.Node
+	for _, c := range candidates {
+		if c.Kind == yaml.AliasNode && c.Alias != nil {
+			c = c.Alias
+		}
+		switch {
+		case seg.hasIndex:
+			if c.Kind != yaml.SequenceNode || seg.index < 0 || seg.index >= len(c.Content) {
+				continue
+			}
+			sub, err := queryNodePath(c.Content[seg.index], rest)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, sub...)
+		case seg.wildcard:
+			var children []*yaml.Node
+			switch c.Kind {
+			case yaml.MappingNode:
+				for i := 1; i < len(c.Content); i += 2 {
+					children = append(children, c.Content[i])
+				}
+			case yaml.SequenceNode:
+				children = append(children, c.Content...)
+			}
+			for _, child := range children {
+				sub, err := queryNodePath(child, rest)
+				if err != nil {
+					return nil, err
+				}
+				matches = append(matches, sub...)
+			}
+		default:
+			if c.Kind != yaml.MappingNode {
+				continue
+			}
+			for i := 0; i+1 < len(c.Content); i += 2 {
+				if c.Content[i].Value == seg.key {
+					sub, err := queryNodePath(c.Content[i+1], rest)
+					if err != nil {
+						return nil, err
+					}
+					matches = append(matches, sub...)
+					break
+				}
+			}
+		}
+	}
+	return matches, nil
+}
+
+// collectNodes returns node and every node reachable from it, used to
+// implement recursive ".." descent.
+func collectNodes(node *yaml.Node) []*yaml.Node {
+	var out []*yaml.Node
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.AliasNode && n.Alias != nil {
+			n = n.Alias
+		}
+		out = append(out, n)
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(node)
+	return out
+}
+
+//------------------------------------------------------------------------------
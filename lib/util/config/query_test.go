@@ -0,0 +1,261 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type branchConf struct {
+	Branch struct {
+		RequestMap string `yaml:"request_map"`
+	} `yaml:"branch"`
+}
+
+type processorsConf struct {
+	Type     string `yaml:"type"`
+	Pipeline struct {
+		Processors []branchConf `yaml:"processors"`
+	} `yaml:"pipeline"`
+}
+
+func exampleProcessorsConf() processorsConf {
+	var c processorsConf
+	c.Type = "foo"
+	c.Pipeline.Processors = make([]branchConf, 2)
+	c.Pipeline.Processors[0].Branch.RequestMap = "root = this"
+	c.Pipeline.Processors[1].Branch.RequestMap = "root = deleted()"
+	return c
+}
+
+func TestParseYAMLPathErrors(t *testing.T) {
+	for _, path := range []string{
+		"pipeline.processors",
+		"$pipeline",
+		"$.processors[",
+		"$.processors[abc]",
+	} {
+		if _, err := parseYAMLPath(path); err == nil {
+			t.Errorf("expected an error parsing %q", path)
+		}
+	}
+}
+
+func TestQueryIndexedField(t *testing.T) {
+	conf := exampleProcessorsConf()
+
+	val, err := Query(conf, "$.pipeline.processors[0].branch.request_map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != "root = this" {
+		t.Errorf("wrong value: %v", val)
+	}
+}
+
+func TestQueryOutOfRangeIndex(t *testing.T) {
+	conf := exampleProcessorsConf()
+	if _, err := Query(conf, "$.pipeline.processors[5].branch.request_map"); err == nil {
+		t.Errorf("expected an error for an out-of-range index")
+	}
+}
+
+func TestQueryWildcard(t *testing.T) {
+	conf := exampleProcessorsConf()
+
+	segs, err := parseYAMLPath("$.pipeline.processors[*].branch.request_map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := nodeFromConf(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := queryNodePath(node, segs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+	if matches[0].Value != "root = this" || matches[1].Value != "root = deleted()" {
+		t.Errorf("unexpected match values: %v, %v", matches[0].Value, matches[1].Value)
+	}
+}
+
+func TestQueryRecursiveDescent(t *testing.T) {
+	conf := exampleProcessorsConf()
+
+	node, err := nodeFromConf(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segs, err := parseYAMLPath("$..request_map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := queryNodePath(node, segs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 recursive matches, got %d", len(matches))
+	}
+}
+
+func TestPatchIndexedField(t *testing.T) {
+	conf := exampleProcessorsConf()
+
+	if err := Patch(&conf, "$.pipeline.processors[1].branch.request_map", "root = patched()"); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Pipeline.Processors[0].Branch.RequestMap != "root = this" {
+		t.Errorf("expected untouched processor to be unaffected, got: %v", conf.Pipeline.Processors[0])
+	}
+	if conf.Pipeline.Processors[1].Branch.RequestMap != "root = patched()" {
+		t.Errorf("expected patched processor to be updated, got: %v", conf.Pipeline.Processors[1])
+	}
+}
+
+func TestPatchWildcardAppliesToEveryMatch(t *testing.T) {
+	conf := exampleProcessorsConf()
+
+	if err := Patch(&conf, "$.pipeline.processors[*].branch.request_map", "root = shared()"); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Pipeline.Processors[0].Branch.RequestMap != "root = shared()" ||
+		conf.Pipeline.Processors[1].Branch.RequestMap != "root = shared()" {
+		t.Fatalf("expected both processors to be patched, got: %+v", conf.Pipeline.Processors)
+	}
+}
+
+// TestApplyPatchValueDeepCopiesValuePerMatch exercises applyPatchValue (the
+// function Patch uses to write a value into every matched node) directly
+// against matches produced by the real query engine, reproducing the bug
+// where a single encoded yaml.Node (and its Content slice) was reused across
+// every matched location: mutating the Content of one match must not be
+// visible through another match sharing the same underlying slice.
+func TestApplyPatchValueDeepCopiesValuePerMatch(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("a: old\nb: old\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+	root := doc.Content[0]
+
+	segs, err := parseYAMLPath("$.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := queryNodePath(root, segs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+
+	type complexVal struct {
+		Items []string `yaml:"items"`
+	}
+	if err := applyPatchValue(matches, complexVal{Items: []string{"x"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// matches[i].Content is [key("items"), sequence]; mutate the sequence's
+	// first element and make sure the other match's equivalent element is
+	// untouched.
+	matches[0].Content[1].Content[0].Value = "mutated"
+	if matches[1].Content[1].Content[0].Value == "mutated" {
+		t.Errorf("expected independent Content slices per match, but a mutation leaked across matches")
+	}
+}
+
+// TestPatchWildcardNonScalarValuesAreIndependentPerMatch calls Patch itself
+// (rather than applyPatchValue directly) with a non-scalar value against a
+// wildcard path matching more than one field, and checks that mutating one
+// patched field afterwards leaves the other unchanged.
+func TestPatchWildcardNonScalarValuesAreIndependentPerMatch(t *testing.T) {
+	conf := exampleProcessorsConf()
+
+	patchVal := struct {
+		Branch struct {
+			RequestMap string `yaml:"request_map"`
+		} `yaml:"branch"`
+	}{}
+	patchVal.Branch.RequestMap = "root = this"
+
+	if err := Patch(&conf, "$.pipeline.processors[*]", patchVal); err != nil {
+		t.Fatal(err)
+	}
+
+	conf.Pipeline.Processors[0].Branch.RequestMap = "root = mutated"
+	if conf.Pipeline.Processors[1].Branch.RequestMap == "root = mutated" {
+		t.Errorf("expected independent values per match, but mutating one processor leaked into another")
+	}
+}
+
+func TestFilterSanitisedPathsInclude(t *testing.T) {
+	san := Sanitised{
+		"type": "foo",
+		"foo": map[string]interface{}{
+			"url":      "http://example.com",
+			"password": "hunter2",
+		},
+	}
+
+	out, err := filterSanitisedPaths(san, []string{"foo.url"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["type"] != "foo" {
+		t.Errorf("expected type field to be preserved, got: %v", out["type"])
+	}
+	foo := out["foo"].(map[string]interface{})
+	if foo["url"] != "http://example.com" {
+		t.Errorf("expected included field to survive, got: %v", foo["url"])
+	}
+	if _, exists := foo["password"]; exists {
+		t.Errorf("expected non-included field to be dropped, got: %v", foo)
+	}
+}
+
+func TestFilterSanitisedPathsExclude(t *testing.T) {
+	san := Sanitised{
+		"type": "foo",
+		"foo": map[string]interface{}{
+			"url":      "http://example.com",
+			"password": "hunter2",
+		},
+	}
+
+	out, err := filterSanitisedPaths(san, nil, []string{"foo.password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	foo := out["foo"].(map[string]interface{})
+	if _, exists := foo["password"]; exists {
+		t.Errorf("expected excluded field to be dropped, got: %v", foo)
+	}
+	if foo["url"] != "http://example.com" {
+		t.Errorf("expected non-excluded field to survive, got: %v", foo["url"])
+	}
+}
+
+func TestFilterSanitisedPathsEqualsDirectMapManipulation(t *testing.T) {
+	san := Sanitised{
+		"type": "foo",
+		"foo": map[string]interface{}{
+			"a": "1",
+			"b": "2",
+		},
+	}
+	out, err := filterSanitisedPaths(san, nil, []string{"foo.a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"foo": map[string]interface{}{"b": "2"}, "type": "foo"}
+	if !reflect.DeepEqual(map[string]interface{}(out), want) {
+		t.Errorf("got %v, want %v", out, want)
+	}
+}
@@ -15,6 +15,47 @@ import (
 // a type field describes the type of the component, and the only other fields
 // returned in the sanitized result are under the namespace of the type.
 func SanitizeComponent(conf interface{}) (Sanitised, error) {
+	return SanitizeComponentWithOpts(conf, SanitizeOpts{})
+}
+
+// SanitizeOpts describes optional transformations to apply when sanitizing a
+// component config, on top of the default behaviour of SanitizeComponent.
+type SanitizeOpts struct {
+	// RemoveSecrets, when set, strips fields that are considered sensitive
+	// (or replaces them with RedactWith, if set) so the result is safe to
+	// log, diff or commit to version control.
+	RemoveSecrets bool
+
+	// RedactWith, when non-empty, is the placeholder value substituted for a
+	// secret field. When empty and RemoveSecrets is set the field is instead
+	// dropped from the result entirely.
+	RedactWith string
+
+	// ResolveEnvVars, when set, expands ${FOO} style environment variable
+	// interpolations found in string fields before the config is sanitized.
+	ResolveEnvVars bool
+
+	// PreserveAnchors, when set, leaves YAML aliases in SanitizeComponentNode
+	// output pointing at their original anchors instead of resolving them to
+	// their anchored content.
+	PreserveAnchors bool
+
+	// IncludePaths, when non-empty, restricts the sanitized result to only
+	// these subtrees (YAMLPath syntax, e.g. "output.timeout" or
+	// "$.output.processors[*].label" - see Query). Mutually complementary
+	// with ExcludePaths, which is applied afterwards.
+	IncludePaths []string
+
+	// ExcludePaths removes the named subtrees (YAMLPath syntax) from the
+	// sanitized result, e.g. to redact a single known-sensitive field
+	// without a full secret-scanner pass.
+	ExcludePaths []string
+}
+
+// SanitizeComponentWithOpts performs the same sanitation as SanitizeComponent
+// but allows the caller to additionally strip secret fields and/or resolve
+// environment variable interpolations via opts.
+func SanitizeComponentWithOpts(conf interface{}, opts SanitizeOpts) (Sanitised, error) {
 	cBytes, err := yaml.Marshal(conf)
 	if err != nil {
 		return nil, err
@@ -25,6 +66,10 @@ func SanitizeComponent(conf interface{}) (Sanitised, error) {
 		return nil, err
 	}
 
+	if opts.ResolveEnvVars {
+		hashMap = resolveEnvVars(hashMap).(map[string]interface{})
+	}
+
 	typeStr, exists := hashMap["type"].(string)
 	if !exists {
 		return nil, errors.New("attempted to sanitize config without a type field")
@@ -38,6 +83,18 @@ func SanitizeComponent(conf interface{}) (Sanitised, error) {
 	} else if pluginConf, exists := hashMap["plugin"]; exists && pluginConf != nil {
 		sanitMap["plugin"] = pluginConf
 	}
+
+	if len(opts.IncludePaths) > 0 || len(opts.ExcludePaths) > 0 {
+		filtered, err := filterSanitisedPaths(sanitMap, opts.IncludePaths, opts.ExcludePaths)
+		if err != nil {
+			return nil, err
+		}
+		sanitMap = filtered
+	}
+
+	if opts.RemoveSecrets {
+		redactSecrets(typeStr, sanitMap, opts.RedactWith)
+	}
 	return sanitMap, nil
 }
 
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+//------------------------------------------------------------------------------
+
+// FieldSpec describes metadata for a single field within a component config.
+// It drives secret redaction as well as JSON Schema generation for the
+// component spec registry.
+type FieldSpec struct {
+	// Name is the field key as it appears in a component config.
+	Name string
+
+	// Secret indicates that the field value is sensitive and should be
+	// stripped or redacted when a config is sanitized.
+	Secret bool
+
+	// Type is the JSON Schema type of the field (e.g. "string", "integer",
+	// "boolean", "object"). Left empty, the field is schema'd as accepting any
+	// type.
+	Type string
+
+	// Description is a human readable summary of the field, surfaced in
+	// generated documentation and JSON Schema output.
+	Description string
+
+	// Default, when non-nil, is the field's default value.
+	Default interface{}
+
+	// Examples lists example values for the field.
+	Examples []interface{}
+
+	// Required indicates the field must be present in the component config.
+	Required bool
+}
+
+// componentSecretFields holds, per component type name, the set of field
+// names declared as secret via RegisterFieldSpecs.
+var componentSecretFields = map[string]map[string]bool{}
+
+// RegisterFieldSpecs registers field metadata for a component type so that
+// SanitizeComponentWithOpts can identify its secret fields in addition to the
+// built-in name heuristic.
+func RegisterFieldSpecs(typeName string, specs []FieldSpec) {
+	secretNames := map[string]bool{}
+	for _, spec := range specs {
+		if spec.Secret {
+			secretNames[spec.Name] = true
+		}
+	}
+	componentSecretFields[typeName] = secretNames
+}
+
+// secretFieldNameHints is a built-in, best-effort heuristic for identifying
+// field names that commonly hold sensitive values. It applies to every
+// component, in addition to any secret fields registered via
+// RegisterFieldSpecs.
+var secretFieldNameHints = []string{
+	"password",
+	"token",
+	"api_key",
+	"secret",
+	"access_key",
+}
+
+func looksLikeSecretField(name string) bool {
+	lower := strings.ToLower(name)
+	for _, hint := range secretFieldNameHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSecrets walks sanitMap in place, redacting or removing any field
+// whose name is known (via a registered FieldSpec) or heuristically believed
+// to hold a secret value.
+func redactSecrets(typeStr string, sanitMap Sanitised, redactWith string) {
+	registered := componentSecretFields[typeStr]
+	for key, val := range sanitMap {
+		if key == "type" {
+			continue
+		}
+		sanitMap[key] = redactSecretsInValue(val, registered, redactWith)
+	}
+}
+
+func redactSecretsInValue(val interface{}, registered map[string]bool, redactWith string) interface{} {
+	switch t := val.(type) {
+	case map[string]interface{}:
+		for k, v := range t {
+			if registered[k] || looksLikeSecretField(k) {
+				if redactWith == "" {
+					delete(t, k)
+				} else {
+					t[k] = redactWith
+				}
+				continue
+			}
+			t[k] = redactSecretsInValue(v, registered, redactWith)
+		}
+		return t
+	case []interface{}:
+		for i, v := range t {
+			t[i] = redactSecretsInValue(v, registered, redactWith)
+		}
+		return t
+	default:
+		return val
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// envVarPattern matches ${FOO} style environment variable interpolations.
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveEnvVars recursively expands ${FOO} interpolations found in string
+// values within conf, replacing them with the current environment variable
+// value (or leaving them untouched if the variable isn't set).
+func resolveEnvVars(conf interface{}) interface{} {
+	switch t := conf.(type) {
+	case map[string]interface{}:
+		for k, v := range t {
+			t[k] = resolveEnvVars(v)
+		}
+		return t
+	case []interface{}:
+		for i, v := range t {
+			t[i] = resolveEnvVars(v)
+		}
+		return t
+	case string:
+		return envVarPattern.ReplaceAllStringFunc(t, func(match string) string {
+			name := envVarPattern.FindStringSubmatch(match)[1]
+			if val, exists := os.LookupEnv(name); exists {
+				return val
+			}
+			return match
+		})
+	default:
+		return conf
+	}
+}
+
+//------------------------------------------------------------------------------
@@ -0,0 +1,67 @@
+package config
+
+import "testing"
+
+func TestRedactSecretsHeuristic(t *testing.T) {
+	sanitMap := Sanitised{
+		"type": "foo",
+		"foo": map[string]interface{}{
+			"password": "hunter2",
+			"url":      "http://example.com",
+		},
+	}
+
+	redactSecrets("foo", sanitMap, "!!secret")
+
+	foo := sanitMap["foo"].(map[string]interface{})
+	if foo["password"] != "!!secret" {
+		t.Errorf("expected password to be redacted, got: %v", foo["password"])
+	}
+	if foo["url"] != "http://example.com" {
+		t.Errorf("expected url to be left alone, got: %v", foo["url"])
+	}
+}
+
+func TestRedactSecretsRegisteredFieldTakesPrecedenceOverNonMatchingName(t *testing.T) {
+	defer delete(componentSecretFields, "bar")
+	RegisterFieldSpecs("bar", []FieldSpec{{Name: "cert", Secret: true}})
+
+	sanitMap := Sanitised{
+		"type": "bar",
+		"bar": map[string]interface{}{
+			"cert": "-----BEGIN CERTIFICATE-----",
+			"name": "unrelated",
+		},
+	}
+
+	redactSecrets("bar", sanitMap, "")
+
+	bar := sanitMap["bar"].(map[string]interface{})
+	if _, exists := bar["cert"]; exists {
+		t.Errorf("expected registered secret field 'cert' to be removed")
+	}
+	if bar["name"] != "unrelated" {
+		t.Errorf("expected unrelated field to be left alone, got: %v", bar["name"])
+	}
+}
+
+func TestResolveEnvVars(t *testing.T) {
+	t.Setenv("CONFIG_TEST_FOO", "bar")
+
+	in := map[string]interface{}{
+		"a": "${CONFIG_TEST_FOO}",
+		"b": "${CONFIG_TEST_MISSING}",
+		"c": []interface{}{"${CONFIG_TEST_FOO}"},
+	}
+
+	out := resolveEnvVars(in).(map[string]interface{})
+	if out["a"] != "bar" {
+		t.Errorf("expected env var to be resolved, got: %v", out["a"])
+	}
+	if out["b"] != "${CONFIG_TEST_MISSING}" {
+		t.Errorf("expected unset env var to be left untouched, got: %v", out["b"])
+	}
+	if out["c"].([]interface{})[0] != "bar" {
+		t.Errorf("expected nested env var to be resolved, got: %v", out["c"])
+	}
+}